@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/1lann/go-tax/pdf"
+	"github.com/1lann/go-tax/statement"
+)
+
+type batchResult struct {
+	file string
+	stmt statement.Statement
+	err  error
+}
+
+func runBatch(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	holdersFile := fs.String("holders-file", "holders.txt",
+		"file listing account holder names, one per line")
+	output := fs.String("output", "", "file to write output to (default stdout)")
+	format := fs.String("format", "ndjson", "output format: json, ndjson, or csv")
+	failFast := fs.Bool("fail-fast", false, "stop scheduling new files after the first failure")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("batch requires exactly one directory")
+	}
+
+	holders, err := loadHolders(*holdersFile)
+	if err != nil {
+		return err
+	}
+
+	files, err := findPDFs(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	results := processConcurrently(files, holders, *failFast)
+
+	var stmts []statement.Statement
+	var failures []string
+	for _, r := range results {
+		if r.file == "" {
+			// Never scheduled, because of -fail-fast.
+			continue
+		}
+		if r.err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", r.file, r.err))
+			continue
+		}
+
+		stmts = append(stmts, r.stmt)
+	}
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer closeOutput()
+
+	if err := writeStatements(w, *format, stmts); err != nil {
+		return err
+	}
+
+	return reportFailures(failures)
+}
+
+func findPDFs(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".pdf") {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// processConcurrently runs pdf.Process over files using a worker pool
+// sized to runtime.NumCPU(), returning one batchResult per file in the
+// same order as files. If failFast is set, workers stop picking up new
+// files as soon as one fails, though files already in flight still finish;
+// a file that was never picked up is left as a zero-value batchResult, with
+// an empty file name.
+func processConcurrently(files []string, holders []string, failFast bool) []batchResult {
+	results := make([]batchResult, len(files))
+	jobs := make(chan int)
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+
+	var wg sync.WaitGroup
+	workers := runtime.NumCPU()
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				s, err := pdf.Process(files[i], holders)
+				results[i] = batchResult{file: files[i], stmt: s, err: err}
+				if err != nil && failFast {
+					stopOnce.Do(func() { close(stop) })
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range files {
+		select {
+		case jobs <- i:
+		case <-stop:
+			break feed
+		}
+	}
+	close(jobs)
+
+	wg.Wait()
+	return results
+}
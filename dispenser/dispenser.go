@@ -6,28 +6,55 @@ import (
 	"strings"
 )
 
+// Kind classifies a single entry dispensed from a Dispenser.
+type Kind int
+
+// The kinds of entries a Dispenser can dispense.
+const (
+	KindWord Kind = iota
+	KindNumeral
+)
+
+type span struct {
+	start, end int // [start, end) into str
+}
+
 // A Dispenser dispenses words, sentences and numerals from an array of words,
-// or a sentence.
+// or a sentence. Sentence boundaries and entry kinds are computed once up
+// front, so navigation never re-scans the underlying text.
 type Dispenser struct {
-	i                    int
-	continueNextSentence bool
-	str                  []string
+	str           []string
+	kinds         []Kind
+	sentences     []span
+	tokenSentence []int // tokenSentence[i] is the sentence index containing str[i]
+
+	pos     int // index into sentences of the current sentence
+	wordPos int // index into str of the next entry to be consumed
 }
 
 // NewDispenser returns a new word dispenser.
 func NewDispenser(str []string) *Dispenser {
-	return &Dispenser{0, false, str}
+	sentences := splitSentences(str)
+
+	return &Dispenser{
+		str:           str,
+		kinds:         classify(str),
+		sentences:     sentences,
+		tokenSentence: indexSentences(sentences, len(str)),
+		pos:           -1,
+	}
 }
 
 // NewDispenserFromSentence returns a new word dispenser from a single sentence.
 func NewDispenserFromSentence(str string) *Dispenser {
 	strArr := strings.Split(str, " ")
-	for k, word := range strArr {
-		strArr[k] = word + " "
+	for k := range strArr {
+		if k != len(strArr)-1 {
+			strArr[k] += " "
+		}
 	}
-	lastWord := strArr[len(strArr)-1]
-	strArr[len(strArr)-1] = lastWord[:len(lastWord)-1]
-	return &Dispenser{0, false, strArr}
+
+	return NewDispenser(strArr)
 }
 
 func getNumeral(str string) (float64, bool) {
@@ -41,173 +68,189 @@ func getNumeral(str string) (float64, bool) {
 	return num, true
 }
 
+// splitSentences finds the [start, end) span of every sentence in str, where
+// an entry ends a sentence if it has no trailing space, or it's the last
+// entry.
+func splitSentences(str []string) []span {
+	var sentences []span
+
+	start := 0
+	for i, word := range str {
+		if i == len(str)-1 || !endsWithSpace(word) {
+			sentences = append(sentences, span{start: start, end: i + 1})
+			start = i + 1
+		}
+	}
+
+	return sentences
+}
+
+func endsWithSpace(word string) bool {
+	return len(word) > 0 && word[len(word)-1] == ' '
+}
+
+// indexSentences builds the reverse lookup from entry index to sentence
+// index, so the sentence containing any entry can be found in O(1).
+func indexSentences(sentences []span, n int) []int {
+	tokenSentence := make([]int, n)
+	for i, s := range sentences {
+		for j := s.start; j < s.end; j++ {
+			tokenSentence[j] = i
+		}
+	}
+
+	return tokenSentence
+}
+
+func classify(str []string) []Kind {
+	kinds := make([]Kind, len(str))
+	for i, word := range str {
+		if _, ok := getNumeral(word); ok {
+			kinds[i] = KindNumeral
+		}
+	}
+
+	return kinds
+}
+
+// currentSentence returns the span of the sentence at d.pos, and whether
+// d.pos refers to a valid sentence.
+func (d *Dispenser) currentSentence() (span, bool) {
+	if d.pos < 0 || d.pos >= len(d.sentences) {
+		return span{}, false
+	}
+
+	return d.sentences[d.pos], true
+}
+
+// sentenceAt returns the index of the sentence containing the entry just
+// before pos, treating pos == 0 as belonging to the first sentence.
+func (d *Dispenser) sentenceAt(pos int) int {
+	if pos <= 0 || len(d.tokenSentence) == 0 {
+		return 0
+	}
+
+	if pos > len(d.tokenSentence) {
+		pos = len(d.tokenSentence)
+	}
+
+	return d.tokenSentence[pos-1]
+}
+
 // LastWord returns the previous word, but does not change the position.
 func (d *Dispenser) LastWord() string {
-	if d.i-2 < 0 || d.i-2 >= len(d.str) {
+	if d.wordPos-2 < 0 || d.wordPos-2 >= len(d.str) {
 		return ""
 	}
 
-	return d.str[d.i-2]
+	return d.str[d.wordPos-2]
 }
 
-// LastNWords returns the last N words, but does not change the position.
+// LastNWords returns the last n words, but does not change the position.
 func (d *Dispenser) LastNWords(n int) string {
-	if d.i-n-1 < 0 || d.i-n-1 >= len(d.str) {
+	end := d.wordPos - 1
+	start := end - n
+	if start < 0 || end <= 0 || end > len(d.str) {
 		return ""
 	}
 
-	return d.str[d.i-2]
+	var sb strings.Builder
+	for i := start; i < end; i++ {
+		sb.WriteString(d.str[i])
+	}
+
+	return sb.String()
 }
 
-// NextWord returns whether or not there's another text word in the current sentence,
-// and jumps to that word.
+// NextWord returns whether or not there's another text word in the current
+// sentence, and jumps to that word.
 func (d *Dispenser) NextWord() bool {
-	if d.i >= len(d.str) {
+	s, ok := d.currentSentence()
+	if !ok || d.wordPos >= s.end {
 		return false
 	}
 
-	if !d.continueNextSentence {
-		return false
-	}
-
-	_, ok := getNumeral(d.str[d.i])
-	if ok {
+	if d.kinds[d.wordPos] == KindNumeral {
 		return false
 	}
 
-	if d.str[d.i][len(d.str[d.i])-1] != ' ' || d.i+1 == len(d.str) {
-		d.continueNextSentence = false
-	}
-
-	d.i++
-
+	d.wordPos++
 	return true
 }
 
 // Word returns the current word.
 func (d *Dispenser) Word() string {
-	if d.i > len(d.str) || d.i == 0 {
+	if d.wordPos <= 0 || d.wordPos > len(d.str) {
 		return ""
 	}
 
-	return strings.TrimSpace(d.str[d.i-1])
+	return strings.TrimSpace(d.str[d.wordPos-1])
 }
 
 // NextSentence jumps to the next sentence, and returns whether or not it's
 // available.
 func (d *Dispenser) NextSentence() bool {
-	if d.i >= len(d.str) {
+	if d.pos+1 >= len(d.sentences) {
+		d.pos = len(d.sentences)
 		return false
 	}
 
-	if !d.continueNextSentence {
-		d.continueNextSentence = true
-	} else {
-		for ; d.i < len(d.str); d.i++ {
-			if d.str[d.i][len(d.str[d.i])-1] != ' ' {
-				d.i++
-				break
-			}
-		}
-
-		if d.i+1 == len(d.str) {
-			d.i++
-			return false
-		} else if d.i >= len(d.str) {
-			return false
-		}
-	}
-
+	d.pos++
+	d.wordPos = d.sentences[d.pos].start
 	return true
 }
 
 // StartOfSentence jumps to the start of the current sentence.
 func (d *Dispenser) StartOfSentence() {
-	if d.i > 0 && d.i < len(d.str) &&
-		d.str[d.i][len(d.str[d.i])-1] != ' ' &&
-		d.str[d.i-1][len(d.str[d.i-1])-1] != ' ' {
-		// Single word sentence
-		if !d.continueNextSentence {
-			d.i--
-			d.continueNextSentence = true
-			return
-		}
-		return
-	}
-
-	if d.i == 0 {
-		d.continueNextSentence = true
-		return
-	}
-
-	if len(d.str) == 1 {
-		d.i = 0
+	if len(d.sentences) == 0 {
 		return
 	}
 
-	if d.i == len(d.str) || !d.continueNextSentence {
-		d.i -= 2
-	}
-
-	if d.i < 0 {
-		d.i = 0
-	}
-
-	d.continueNextSentence = true
-
-	for ; d.i > 0; d.i-- {
-		if d.str[d.i][len(d.str[d.i])-1] != ' ' {
-			d.i++
-			return
-		}
+	if _, ok := d.currentSentence(); !ok {
+		d.pos = d.sentenceAt(d.wordPos)
 	}
+	d.wordPos = d.sentences[d.pos].start
 }
 
 // LastSentence jumps to the start of the last sentence.
 func (d *Dispenser) LastSentence() {
-	d.StartOfSentence()
-
-	if d.str[d.i][len(d.str[d.i])-1] != ' ' {
-		// Also the end
-		d.i--
+	if len(d.sentences) == 0 {
 		return
 	}
 
-	d.i -= 2
-	if d.i <= 0 {
-		d.i = 1
-		return
+	if _, ok := d.currentSentence(); !ok {
+		d.pos = d.sentenceAt(d.wordPos)
+	}
+	d.pos--
+	if d.pos < 0 {
+		d.pos = 0
 	}
-	d.StartOfSentence()
+
+	d.wordPos = d.sentences[d.pos].start
 }
 
 // DumpSentence returns the entire current sentence from the current position
 // as a string, including numerals.
 func (d *Dispenser) DumpSentence() string {
-	if !d.continueNextSentence {
+	s, ok := d.currentSentence()
+	if !ok {
 		return ""
 	}
 
-	sentence := ""
-	for ; d.i < len(d.str); d.i++ {
-		sentence += d.str[d.i]
-		if d.str[d.i][len(d.str[d.i])-1] != ' ' {
-			d.i++
-			break
-		}
+	var sb strings.Builder
+	for ; d.wordPos < s.end; d.wordPos++ {
+		sb.WriteString(d.str[d.wordPos])
 	}
 
-	d.continueNextSentence = false
-
-	return sentence
+	return sb.String()
 }
 
-// DumpNSentences returns the next N sentences without changing the position.
+// DumpNSentences returns the next n sentences without changing the position.
 func (d *Dispenser) DumpNSentences(n int) []string {
-	start := d.i
-	continueState := d.continueNextSentence
+	pos, wordPos := d.pos, d.wordPos
 
-	sentences := []string{d.DumpSentence()}
+	sentences := make([]string, 0, n)
+	sentences = append(sentences, d.DumpSentence())
 	for i := 0; i < n-1; i++ {
 		if !d.NextSentence() {
 			break
@@ -216,79 +259,62 @@ func (d *Dispenser) DumpNSentences(n int) []string {
 		sentences = append(sentences, d.DumpSentence())
 	}
 
-	d.i = start
-	d.continueNextSentence = continueState
-
+	d.pos, d.wordPos = pos, wordPos
 	return sentences
 }
 
 // AtEndOfSentence returns whether or not the end of the sentence has been reached.
 // It is theoretically the equivelant to NextWord() and NextNumeral() returning false.
 func (d *Dispenser) AtEndOfSentence() bool {
-	if !d.continueNextSentence {
+	s, ok := d.currentSentence()
+	if !ok {
 		return true
 	}
 
-	if d.i >= len(d.str) {
-		return true
-	}
-
-	return false
+	return d.wordPos >= s.end
 }
 
 // NextNumeral returns whether or not the next word in the current sentence is
 // a numeral, and jumps to the word.
 func (d *Dispenser) NextNumeral() bool {
-	if d.i >= len(d.str) {
+	s, ok := d.currentSentence()
+	if !ok || d.wordPos >= s.end {
 		return false
 	}
 
-	if !d.continueNextSentence {
+	if d.kinds[d.wordPos] != KindNumeral {
 		return false
 	}
 
-	_, ok := getNumeral(d.str[d.i])
-	if ok {
-		if d.str[d.i][len(d.str[d.i])-1] != ' ' {
-			d.continueNextSentence = false
-		}
-
-		d.i++
-
-		return true
-	}
-
-	return false
+	d.wordPos++
+	return true
 }
 
 // Position returns the current position in the text.
 func (d *Dispenser) Position() int {
-	return d.i
+	return d.wordPos
 }
 
 // JumpNextNumeral jumps to the next numeral in the sentence and returns true,
 // or if there is no other numeral in the sentence, it does not jump at all and
 // returns false.
 func (d *Dispenser) JumpNextNumeral() bool {
-	start := d.i
+	s, ok := d.currentSentence()
+	if !ok {
+		return false
+	}
 
-	for !d.AtEndOfSentence() {
-		if d.NextNumeral() {
-			d.i--
-			d.continueNextSentence = true
-			break
-		} else {
-			d.NextWord()
-		}
+	start := d.wordPos
+	for d.wordPos < s.end && d.kinds[d.wordPos] != KindNumeral {
+		d.wordPos++
 	}
 
-	hasNext := d.NextNumeral()
-	if !hasNext && d.AtEndOfSentence() {
-		d.i = start
+	if d.wordPos >= s.end {
+		d.wordPos = start
 		return false
 	}
 
-	d.continueNextSentence = true
+	d.wordPos++
 	return true
 }
 
@@ -296,11 +322,11 @@ func (d *Dispenser) JumpNextNumeral() bool {
 //
 // Examples of numerals: 4, 3.4, $4.50.
 func (d *Dispenser) Numeral() float64 {
-	if d.i > len(d.str) || d.i == 0 {
+	if d.wordPos <= 0 || d.wordPos > len(d.str) {
 		return 0
 	}
 
-	num, ok := getNumeral(d.str[d.i-1])
+	num, ok := getNumeral(d.str[d.wordPos-1])
 	if ok {
 		return num
 	}
@@ -0,0 +1,64 @@
+package dispenser
+
+import (
+	"strings"
+	"testing"
+)
+
+// sentenceWords splits group on spaces and applies the trailing-space
+// convention NewDispenser expects: every word carries a trailing space
+// except the last word of its sentence.
+func sentenceWords(group string) []string {
+	words := strings.Split(group, " ")
+	for i, w := range words {
+		if i != len(words)-1 {
+			w += " "
+		}
+		words[i] = w
+	}
+
+	return words
+}
+
+// FuzzDispenser checks the two invariants the rest of the package relies on:
+// dumping every sentence reconstructs the original text, and StartOfSentence
+// undoes a DumpSentence, returning to the position it started from. Sentence
+// groups are encoded as "|"-separated runs of words, so a single fuzzed
+// string can exercise multi-sentence []string input via NewDispenser
+// directly, not just the single-sentence NewDispenserFromSentence path.
+func FuzzDispenser(f *testing.F) {
+	f.Add("Hello world, this is a sentence.|Another one here.")
+	f.Add("")
+	f.Add("   ")
+	f.Add("42.50 dollars were paid|on 1 July 2023")
+	f.Add("a")
+	f.Add("||a")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		groups := strings.Split(s, "|")
+
+		var str []string
+		for _, g := range groups {
+			str = append(str, sentenceWords(g)...)
+		}
+
+		d := NewDispenser(str)
+
+		var got strings.Builder
+		for d.NextSentence() {
+			before := d.Position()
+			got.WriteString(d.DumpSentence())
+
+			d.StartOfSentence()
+			if d.Position() != before {
+				t.Fatalf("StartOfSentence() after DumpSentence() landed on %d, want %d",
+					d.Position(), before)
+			}
+		}
+
+		want := strings.Join(groups, "")
+		if got.String() != want {
+			t.Fatalf("NextSentence()+DumpSentence() reconstructed %q, want %q", got.String(), want)
+		}
+	})
+}
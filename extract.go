@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/1lann/go-tax/pdf"
+	"github.com/1lann/go-tax/statement"
+)
+
+func runExtract(args []string) error {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	holdersFile := fs.String("holders-file", "holders.txt",
+		"file listing account holder names, one per line")
+	output := fs.String("output", "", "file to write output to (default stdout)")
+	format := fs.String("format", "json", "output format: json, ndjson, or csv")
+	failFast := fs.Bool("fail-fast", false, "stop at the first file that fails to process")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("extract requires at least one PDF file")
+	}
+
+	holders, err := loadHolders(*holdersFile)
+	if err != nil {
+		return err
+	}
+
+	var stmts []statement.Statement
+	var failures []string
+	for _, file := range fs.Args() {
+		s, err := pdf.Process(file, holders)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", file, err))
+			if *failFast {
+				break
+			}
+			continue
+		}
+
+		stmts = append(stmts, s)
+	}
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer closeOutput()
+
+	if err := writeStatements(w, *format, stmts); err != nil {
+		return err
+	}
+
+	return reportFailures(failures)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// loadHolders reads a newline-separated list of account holder names from
+// path, used to recognize which holder(s) a statement belongs to.
+func loadHolders(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	holders := strings.Split(string(data), "\n")
+	if len(holders) > 0 && holders[len(holders)-1] == "" {
+		holders = holders[:len(holders)-1]
+	}
+
+	return holders, nil
+}
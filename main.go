@@ -0,0 +1,47 @@
+// Command go-tax extracts dividend statement data from PDFs and reports it
+// in a form suitable for tax time.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "extract":
+		err = runExtract(os.Args[2:])
+	case "batch":
+		err = runBatch(os.Args[2:])
+	case "summarize":
+		err = runSummarize(os.Args[2:])
+	case "-h", "-help", "--help":
+		usage()
+		return
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "go-tax:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: go-tax <command> [flags] <files...>
+
+commands:
+  extract <files...>      extract statements from one or more PDFs
+  batch <dir>             extract statements from every PDF in a directory
+  summarize <files...>    aggregate statements by financial year
+
+run "go-tax <command> -h" for a command's flags`)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/1lann/go-tax/statement"
+)
+
+// openOutput opens path for writing, or returns os.Stdout if path is
+// empty. The returned close func must be called once writing is done.
+func openOutput(path string) (w io.Writer, closeOutput func() error, err error) {
+	if path == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, f.Close, nil
+}
+
+// writeStatements writes stmts to w in the given format: "json" for a
+// single indented JSON array, "ndjson" for one JSON object per line, or
+// "csv" for the fixed column set used by the batch summary.
+func writeStatements(w io.Writer, format string, stmts []statement.Statement) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(stmts)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, s := range stmts {
+			if err := enc.Encode(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		return writeCSV(w, stmts)
+	default:
+		return fmt.Errorf("unknown format %q, want json, ndjson, or csv", format)
+	}
+}
+
+var csvHeader = []string{"Entity", "ASXCode", "PaymentDate", "Franked",
+	"Unfranked", "FrankingCredit", "WithholdingTax", "TotalPayment"}
+
+func writeCSV(w io.Writer, stmts []statement.Statement) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, s := range stmts {
+		row := []string{
+			s.Entity,
+			s.ASXCode,
+			s.PaymentDate.Format("2006-01-02"),
+			dollarString(s.FrankedAmount),
+			dollarString(s.UnfrankedAmount),
+			dollarString(s.FrankingCredit),
+			dollarString(s.WithholdingTax),
+			dollarString(s.TotalPayment),
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func dollarString(d statement.Dollar) string {
+	if !d.HasValue {
+		return ""
+	}
+
+	return strconv.FormatFloat(float64(d.Cents)/100, 'f', 2, 64)
+}
+
+// reportFailures prints a per-file error summary to stderr and, if there
+// were any failures, returns a non-nil error so the process exits
+// non-zero.
+func reportFailures(failures []string) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "go-tax: failed to process %d file(s):\n", len(failures))
+	for _, f := range failures {
+		fmt.Fprintln(os.Stderr, " -", f)
+	}
+
+	return fmt.Errorf("%d file(s) failed to process", len(failures))
+}
+
+// reportSkipped prints a summary of statements that were excluded from a
+// report for benign reasons (wrong currency, no payment date) rather than a
+// processing error, so it never affects the process's exit status.
+func reportSkipped(skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "go-tax: skipped %d statement(s):\n", len(skipped))
+	for _, s := range skipped {
+		fmt.Fprintln(os.Stderr, " -", s)
+	}
+}
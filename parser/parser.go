@@ -0,0 +1,156 @@
+// Package parser matches issuer-specific label/value rules against a
+// stream of statement tokens, standing in for the single-pass reduction
+// step of a yacc-style grammar. It exists to replace ad-hoc, positional
+// queues (label N pairs with numeral N) that desynchronize whenever a
+// label has no matching value nearby.
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ValueType is the kind of value a Rule expects to find following its
+// label.
+type ValueType int
+
+const (
+	Dollar ValueType = iota
+	Int
+	Date
+)
+
+// HeaderType identifies the statement field a Rule resolves to.
+type HeaderType int
+
+const (
+	HeaderFranked HeaderType = iota
+	HeaderUnfranked
+	HeaderFrankingCredit
+	HeaderWithholdingTax
+	HeaderSharesAllotted
+	HeaderCostOfSharesAllotted
+	HeaderTotalShares
+	HeaderTotalPayment
+	HeaderPaymentDate
+	HeaderOther
+)
+
+func (h HeaderType) String() string {
+	switch h {
+	case HeaderFranked:
+		return "Franked"
+	case HeaderUnfranked:
+		return "Unfranked"
+	case HeaderFrankingCredit:
+		return "Franking Credit"
+	case HeaderWithholdingTax:
+		return "Withholding Tax"
+	case HeaderSharesAllotted:
+		return "Shares Allotted"
+	case HeaderCostOfSharesAllotted:
+		return "Cost Of Shares Allotted"
+	case HeaderTotalShares:
+		return "Total Shares"
+	case HeaderTotalPayment:
+		return "Total Payment"
+	case HeaderPaymentDate:
+		return "Payment Date"
+	case HeaderOther:
+		return "Other"
+	default:
+		return "Unknown"
+	}
+}
+
+// Rule describes a label to look for in a token stream, and the kind of
+// value expected to follow it within MaxDistance tokens.
+type Rule struct {
+	Label       *regexp.Regexp
+	Value       ValueType
+	MaxDistance int
+	Header      HeaderType
+}
+
+// Match is a Rule that has been resolved against an actual value token.
+type Match struct {
+	Header HeaderType
+	Value  ValueType
+	Text   string
+}
+
+type pendingMatch struct {
+	rule  Rule
+	since int
+}
+
+// Parser matches a table of Rules against a stream of tokens fed one at a
+// time. Each token can open new pending matches by its label, and can
+// close the oldest still-live pending match if it satisfies that rule's
+// value type. A pending match that outlives its MaxDistance is discarded
+// rather than being left to bind to an unrelated later token, and each
+// Header only ever resolves once.
+type Parser struct {
+	rules   []Rule
+	pending []pendingMatch
+	tick    int
+	done    map[HeaderType]bool
+}
+
+// NewParser returns a Parser driven by the given rule table, such as
+// Computershare, LinkMarketServices or Boardroom.
+func NewParser(rules []Rule) *Parser {
+	return &Parser{rules: rules, done: make(map[HeaderType]bool)}
+}
+
+// Feed advances the parser by one token and reports a Match if doing so
+// resolved a pending rule.
+func (p *Parser) Feed(tok string) (Match, bool) {
+	p.tick++
+
+	for _, rule := range p.rules {
+		if p.done[rule.Header] {
+			continue
+		}
+		if rule.Label.MatchString(tok) {
+			p.pending = append(p.pending, pendingMatch{rule: rule, since: p.tick})
+		}
+	}
+
+	for i := 0; i < len(p.pending); i++ {
+		pm := p.pending[i]
+		if p.done[pm.rule.Header] || p.tick-pm.since > pm.rule.MaxDistance {
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+			i--
+			continue
+		}
+
+		if matchesValue(pm.rule.Value, tok) {
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+			p.done[pm.rule.Header] = true
+			return Match{Header: pm.rule.Header, Value: pm.rule.Value, Text: tok}, true
+		}
+	}
+
+	return Match{}, false
+}
+
+var (
+	dollarPattern = regexp.MustCompile(`^\$?[0-9,]+(\.[0-9]+)?$`)
+	intPattern    = regexp.MustCompile(`^[0-9,]+$`)
+	datePattern   = regexp.MustCompile(`^\d{1,2} [A-Za-z]+ \d{4}$`)
+)
+
+func matchesValue(v ValueType, tok string) bool {
+	tok = strings.TrimSpace(tok)
+	switch v {
+	case Dollar:
+		return dollarPattern.MatchString(tok)
+	case Int:
+		return intPattern.MatchString(tok)
+	case Date:
+		return datePattern.MatchString(tok)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestParserFeed(t *testing.T) {
+	rules := []Rule{
+		{Label: regexp.MustCompile(`(?i)^franked amount`), Value: Dollar, MaxDistance: 5, Header: HeaderFranked},
+		{Label: regexp.MustCompile(`(?i)^unfranked`), Value: Dollar, MaxDistance: 5, Header: HeaderUnfranked},
+		{Label: regexp.MustCompile(`(?i)^payment date`), Value: Date, MaxDistance: 2, Header: HeaderPaymentDate},
+	}
+
+	tests := []struct {
+		name   string
+		tokens []string
+		want   map[HeaderType]string
+	}{
+		{
+			name:   "label immediately followed by value",
+			tokens: []string{"franked amount", "$150.00"},
+			want:   map[HeaderType]string{HeaderFranked: "$150.00"},
+		},
+		{
+			name:   "unrelated tokens between label and value",
+			tokens: []string{"unfranked", "see note 1", "123.45"},
+			want:   map[HeaderType]string{HeaderUnfranked: "123.45"},
+		},
+		{
+			name:   "value arrives after MaxDistance expires",
+			tokens: []string{"payment date", "a", "b", "c", "15 March 2023"},
+			want:   map[HeaderType]string{},
+		},
+		{
+			name:   "each header only resolves once",
+			tokens: []string{"franked amount", "$1.00", "franked amount", "$2.00"},
+			want:   map[HeaderType]string{HeaderFranked: "$1.00"},
+		},
+		{
+			name:   "value of the wrong type never resolves the label",
+			tokens: []string{"payment date", "not a date"},
+			want:   map[HeaderType]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(rules)
+			got := make(map[HeaderType]string)
+
+			for _, tok := range tt.tokens {
+				if match, ok := p.Feed(tok); ok {
+					got[match.Header] = match.Text
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("resolved %v, want %v", got, tt.want)
+			}
+			for header, text := range tt.want {
+				if got[header] != text {
+					t.Errorf("%v resolved to %q, want %q", header, got[header], text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import "regexp"
+
+// Computershare is the rule table for dividend statements issued by
+// Computershare Investor Services, the most common ASX share registry.
+var Computershare = []Rule{
+	{Label: regexp.MustCompile(`(?i)^franked amount`), Value: Dollar, MaxDistance: 5, Header: HeaderFranked},
+	{Label: regexp.MustCompile(`(?i)^unfranked`), Value: Dollar, MaxDistance: 5, Header: HeaderUnfranked},
+	{Label: regexp.MustCompile(`(?i)^(less )?withholding tax`), Value: Dollar, MaxDistance: 5, Header: HeaderWithholdingTax},
+	{Label: regexp.MustCompile(`(?i)^number of shares allotted`), Value: Int, MaxDistance: 5, Header: HeaderSharesAllotted},
+	{Label: regexp.MustCompile(`(?i)^cost of shares allotted`), Value: Dollar, MaxDistance: 5, Header: HeaderCostOfSharesAllotted},
+	{Label: regexp.MustCompile(`(?i)^total shares`), Value: Int, MaxDistance: 5, Header: HeaderTotalShares},
+	{Label: regexp.MustCompile(`(?i)^total (payment|amount)`), Value: Dollar, MaxDistance: 5, Header: HeaderTotalPayment},
+	{Label: regexp.MustCompile(`(?i)^franking credit`), Value: Dollar, MaxDistance: 5, Header: HeaderFrankingCredit},
+	{Label: regexp.MustCompile(`(?i)^dividend rate`), Value: Dollar, MaxDistance: 5, Header: HeaderOther},
+	{Label: regexp.MustCompile(`(?i)^participating (shares|holding)`), Value: Int, MaxDistance: 5, Header: HeaderOther},
+	{Label: regexp.MustCompile(`(?i)^net amount`), Value: Dollar, MaxDistance: 5, Header: HeaderOther},
+	{Label: regexp.MustCompile(`(?i)^dividend reinvestment plan amount`), Value: Dollar, MaxDistance: 5, Header: HeaderOther},
+	{Label: regexp.MustCompile(`(?i)^cash balance (brought|carried) forward`), Value: Dollar, MaxDistance: 5, Header: HeaderOther},
+	{Label: regexp.MustCompile(`(?i)^amount available from this payment`), Value: Dollar, MaxDistance: 5, Header: HeaderOther},
+	{Label: regexp.MustCompile(`(?i)^total amount available for reinvestment`), Value: Dollar, MaxDistance: 5, Header: HeaderOther},
+}
+
+// LinkMarketServices is the rule table for statements issued by Link
+// Market Services (now MUFG Corporate Markets), which favours "dividend"
+// over "amount" in its labels.
+var LinkMarketServices = []Rule{
+	{Label: regexp.MustCompile(`(?i)^franked dividend`), Value: Dollar, MaxDistance: 5, Header: HeaderFranked},
+	{Label: regexp.MustCompile(`(?i)^unfranked dividend`), Value: Dollar, MaxDistance: 5, Header: HeaderUnfranked},
+	{Label: regexp.MustCompile(`(?i)^tfn withholding tax`), Value: Dollar, MaxDistance: 5, Header: HeaderWithholdingTax},
+	{Label: regexp.MustCompile(`(?i)^shares allotted under the drp`), Value: Int, MaxDistance: 5, Header: HeaderSharesAllotted},
+	{Label: regexp.MustCompile(`(?i)^issue price`), Value: Dollar, MaxDistance: 5, Header: HeaderCostOfSharesAllotted},
+	{Label: regexp.MustCompile(`(?i)^total holding`), Value: Int, MaxDistance: 5, Header: HeaderTotalShares},
+	{Label: regexp.MustCompile(`(?i)^total dividend`), Value: Dollar, MaxDistance: 5, Header: HeaderTotalPayment},
+	{Label: regexp.MustCompile(`(?i)^franking credits?`), Value: Dollar, MaxDistance: 5, Header: HeaderFrankingCredit},
+}
+
+// Boardroom is the rule table for statements issued by Boardroom Pty
+// Limited.
+var Boardroom = []Rule{
+	{Label: regexp.MustCompile(`(?i)^franked amount`), Value: Dollar, MaxDistance: 5, Header: HeaderFranked},
+	{Label: regexp.MustCompile(`(?i)^unfranked amount`), Value: Dollar, MaxDistance: 5, Header: HeaderUnfranked},
+	{Label: regexp.MustCompile(`(?i)^withholding tax deducted`), Value: Dollar, MaxDistance: 5, Header: HeaderWithholdingTax},
+	{Label: regexp.MustCompile(`(?i)^new shares issued`), Value: Int, MaxDistance: 5, Header: HeaderSharesAllotted},
+	{Label: regexp.MustCompile(`(?i)^reinvestment price`), Value: Dollar, MaxDistance: 5, Header: HeaderCostOfSharesAllotted},
+	{Label: regexp.MustCompile(`(?i)^balance of shares`), Value: Int, MaxDistance: 5, Header: HeaderTotalShares},
+	{Label: regexp.MustCompile(`(?i)^total payment`), Value: Dollar, MaxDistance: 5, Header: HeaderTotalPayment},
+	{Label: regexp.MustCompile(`(?i)^gross franking credit`), Value: Dollar, MaxDistance: 5, Header: HeaderFrankingCredit},
+}
+
+// US1099DIV is the rule table for US Form 1099-DIV dividend statements.
+// It only covers the boxes that have an equivalent in statement.Statement.
+// A 1099-DIV is an annual summary rather than a per-dividend notice, so it
+// has no single payment date to extract; callers that need one (e.g. to
+// bucket a statement into a financial year) will need to supply it from
+// elsewhere.
+var US1099DIV = []Rule{
+	{Label: regexp.MustCompile(`(?i)^total ordinary dividends`), Value: Dollar, MaxDistance: 5, Header: HeaderTotalPayment},
+	{Label: regexp.MustCompile(`(?i)^qualified dividends`), Value: Dollar, MaxDistance: 5, Header: HeaderFranked},
+	{Label: regexp.MustCompile(`(?i)^federal income tax withheld`), Value: Dollar, MaxDistance: 5, Header: HeaderWithholdingTax},
+	{Label: regexp.MustCompile(`(?i)^total capital gain distr`), Value: Dollar, MaxDistance: 5, Header: HeaderOther},
+}
+
+// UKDividendVoucher is the rule table for UK dividend vouchers, which pair
+// a net dividend with its tax credit.
+var UKDividendVoucher = []Rule{
+	{Label: regexp.MustCompile(`(?i)^net dividend`), Value: Dollar, MaxDistance: 5, Header: HeaderTotalPayment},
+	{Label: regexp.MustCompile(`(?i)^tax credit`), Value: Dollar, MaxDistance: 5, Header: HeaderFrankingCredit},
+	{Label: regexp.MustCompile(`(?i)^dividend per share`), Value: Dollar, MaxDistance: 5, Header: HeaderOther},
+	{Label: regexp.MustCompile(`(?i)^dividend date`), Value: Date, MaxDistance: 5, Header: HeaderPaymentDate},
+}
+
+// NZImputationCredits is the rule table for New Zealand dividend
+// statements that quote imputation credits.
+var NZImputationCredits = []Rule{
+	{Label: regexp.MustCompile(`(?i)^gross dividend`), Value: Dollar, MaxDistance: 5, Header: HeaderTotalPayment},
+	{Label: regexp.MustCompile(`(?i)^imputation credit`), Value: Dollar, MaxDistance: 5, Header: HeaderFrankingCredit},
+	{Label: regexp.MustCompile(`(?i)^resident withholding tax`), Value: Dollar, MaxDistance: 5, Header: HeaderWithholdingTax},
+	{Label: regexp.MustCompile(`(?i)^payment date`), Value: Date, MaxDistance: 5, Header: HeaderPaymentDate},
+}
@@ -0,0 +1,245 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1lann/go-tax/dispenser"
+	"github.com/1lann/go-tax/parser"
+	"github.com/1lann/go-tax/statement"
+)
+
+// Extractor recognizes and extracts a particular issuer's or jurisdiction's
+// dividend statement format from a document's already-extracted text.
+type Extractor interface {
+	// Detect reports whether text looks like a statement this Extractor
+	// knows how to parse.
+	Detect(text []string) bool
+
+	// Extract parses text into a Statement.
+	Extract(text []string, holders []string) (statement.Statement, error)
+}
+
+// Extractors is the set of registered Extractors. Process tries each in
+// turn, in order, and uses the first one whose Detect reports a match.
+var Extractors = []Extractor{
+	ASXExtractor,
+	US1099DIVExtractor,
+	UKDividendVoucherExtractor,
+	NZImputationExtractor,
+}
+
+func joinedLower(text []string) string {
+	return strings.ToLower(strings.Join(text, ""))
+}
+
+type asxExtractor struct{}
+
+func (asxExtractor) Detect(text []string) bool {
+	joined := joinedLower(text)
+	return strings.Contains(joined, "franking credit") ||
+		strings.Contains(joined, "asx code")
+}
+
+// asxRegistries are the share registries processText has a rule table for,
+// tried in this order by registry name, and as a fallback, in this order by
+// number of fields resolved.
+var asxRegistries = []struct {
+	name  string
+	rules []parser.Rule
+}{
+	{"computershare", parser.Computershare},
+	{"link market services", parser.LinkMarketServices},
+	{"boardroom", parser.Boardroom},
+}
+
+// resolvedFieldCount counts how many of s's numeric fields processText
+// managed to fill in, used to score a rule table against text whose
+// registry couldn't be identified by name.
+func resolvedFieldCount(s statement.Statement) int {
+	count := 0
+	for _, d := range []statement.Dollar{
+		s.TotalPayment, s.FrankingCredit, s.UnfrankedAmount,
+		s.FrankedAmount, s.WithholdingTax, s.CostOfSharesAllotted,
+	} {
+		if d.HasValue {
+			count++
+		}
+	}
+
+	if s.SharesAllotted != 0 {
+		count++
+	}
+	if s.TotalShares != 0 {
+		count++
+	}
+
+	return count
+}
+
+func (asxExtractor) Extract(text []string, holders []string) (statement.Statement, error) {
+	joined := joinedLower(text)
+	for _, registry := range asxRegistries {
+		if !strings.Contains(joined, registry.name) {
+			continue
+		}
+
+		s, err := processText(text, holders, registry.rules)
+		if err != nil {
+			return s, err
+		}
+
+		s.Currency = "AUD"
+		return s, nil
+	}
+
+	// The registry wasn't named anywhere in the text. Try every rule table
+	// and keep whichever one resolved the most fields.
+	var best statement.Statement
+	bestCount := -1
+	for _, registry := range asxRegistries {
+		s, err := processText(text, holders, registry.rules)
+		if err != nil {
+			return s, err
+		}
+
+		if count := resolvedFieldCount(s); count > bestCount {
+			best, bestCount = s, count
+		}
+	}
+
+	best.Currency = "AUD"
+	return best, nil
+}
+
+// ASXExtractor recognizes and extracts Australian ASX-listed dividend
+// statements: franking credits, DRP share allotments, and withholding tax.
+// It tries the Computershare, Link Market Services and Boardroom rule
+// tables, since labels differ by share registry.
+var ASXExtractor Extractor = asxExtractor{}
+
+// genericExtract runs rules over text looking for holder names and rule
+// matches, without any of the ASX-specific entity/ABN handling in
+// findOtherData — it doesn't derive Entity or an ASXCode-equivalent for
+// these jurisdictions.
+func genericExtract(text []string, holders []string, rules []parser.Rule,
+	currency string) (statement.Statement, error) {
+	d := dispenser.NewDispenser(text)
+
+	var state statement.Statement
+	state.Currency = currency
+	p := parser.NewParser(rules)
+
+	for d.NextSentence() {
+		sentence := d.DumpSentence()
+
+		if match, ok := p.Feed(strings.ToLower(sentence)); ok {
+			applyGenericMatch(&state, match)
+			continue
+		}
+
+		d.StartOfSentence()
+		newSentence := strings.Join(d.DumpNSentences(5), " ")
+
+		if len(state.AccountHolders) == 0 {
+			for _, holder := range holders {
+				if strings.Contains(strings.ToLower(newSentence),
+					strings.ToLower(holder)) {
+					state.AccountHolders = append(state.AccountHolders, holder)
+				}
+			}
+		}
+
+		if match, ok := p.Feed(strings.ToLower(newSentence)); ok {
+			applyGenericMatch(&state, match)
+			continue
+		}
+
+		numD := dispenser.NewDispenserFromSentence(sentence)
+		numD.NextSentence()
+		if found := numD.JumpNextNumeral(); found && numD.Position() < 5 {
+			if match, ok := p.Feed(numD.Word()); ok {
+				applyMatch(&state, match.Header, numD.Numeral())
+			}
+		}
+	}
+
+	return state, nil
+}
+
+// applyGenericMatch updates state from a Match resolved at the sentence
+// level, where (unlike the isolated numeral matched by JumpNextNumeral) the
+// matched text is the raw token genericExtract or processText fed the parser, such as a
+// whole "15 march 2023" sentence for a HeaderPaymentDate rule.
+func applyGenericMatch(state *statement.Statement, match parser.Match) {
+	if match.Header == parser.HeaderPaymentDate {
+		if t, err := time.Parse(dateLayout, strings.TrimSpace(match.Text)); err == nil {
+			state.PaymentDate = t
+		}
+		return
+	}
+
+	if num, ok := parseMatchValue(match.Text); ok {
+		applyMatch(state, match.Header, num)
+	}
+}
+
+func parseMatchValue(text string) (float64, bool) {
+	clean := strings.ReplaceAll(strings.TrimSpace(text), ",", "")
+	clean = strings.TrimLeft(clean, "$")
+	num, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return num, true
+}
+
+type us1099DIVExtractor struct{}
+
+func (us1099DIVExtractor) Detect(text []string) bool {
+	joined := joinedLower(text)
+	return strings.Contains(joined, "1099-div") ||
+		strings.Contains(joined, "total ordinary dividends")
+}
+
+func (us1099DIVExtractor) Extract(text []string, holders []string) (statement.Statement, error) {
+	return genericExtract(text, holders, parser.US1099DIV, "USD")
+}
+
+// US1099DIVExtractor recognizes and extracts US Form 1099-DIV dividend
+// statements. It only covers the handful of boxes that map onto
+// statement.Statement (total ordinary dividends, qualified dividends, and
+// federal income tax withheld).
+var US1099DIVExtractor Extractor = us1099DIVExtractor{}
+
+type ukDividendVoucherExtractor struct{}
+
+func (ukDividendVoucherExtractor) Detect(text []string) bool {
+	joined := joinedLower(text)
+	return strings.Contains(joined, "dividend voucher") ||
+		(strings.Contains(joined, "net dividend") && strings.Contains(joined, "tax credit"))
+}
+
+func (ukDividendVoucherExtractor) Extract(text []string, holders []string) (statement.Statement, error) {
+	return genericExtract(text, holders, parser.UKDividendVoucher, "GBP")
+}
+
+// UKDividendVoucherExtractor recognizes and extracts UK dividend vouchers,
+// which quote a net dividend alongside its associated tax credit.
+var UKDividendVoucherExtractor Extractor = ukDividendVoucherExtractor{}
+
+type nzImputationExtractor struct{}
+
+func (nzImputationExtractor) Detect(text []string) bool {
+	return strings.Contains(joinedLower(text), "imputation credit")
+}
+
+func (nzImputationExtractor) Extract(text []string, holders []string) (statement.Statement, error) {
+	return genericExtract(text, holders, parser.NZImputationCredits, "NZD")
+}
+
+// NZImputationExtractor recognizes and extracts New Zealand dividend
+// statements that quote imputation credits and resident withholding tax.
+var NZImputationExtractor Extractor = nzImputationExtractor{}
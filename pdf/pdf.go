@@ -8,59 +8,113 @@ import (
 	"github.com/1lann/go-pdfreader/fancy"
 	"github.com/1lann/go-pdfreader/ps"
 	"github.com/1lann/go-tax/dispenser"
+	"github.com/1lann/go-tax/parser"
 	"github.com/1lann/go-tax/statement"
+	"math"
+	"os"
 	"runtime/debug"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
-type headerType int
-
-const (
-	headerFranked headerType = iota
-	headerUnfranked
-	headerFrankingCredit
-	headerWithholdingTax
-	headerSharesAllotted
-	headerCostOfSharesAllotted
-	headerTotalShares
-	headerTotalPayment
-	headerOther
-)
+// TextToken is a single piece of text pulled out of a page's content
+// stream, positioned in unrotated page space. Tokens are emitted in the
+// order they're drawn, which for most statements is reading order, but
+// callers that need to deal with multi-column layouts should group tokens
+// by Y (and then X) instead of relying on stream order.
+//
+// X, Y and FontSize are exposed for exactly that kind of caller. Process
+// and ProcessPages feed the registered Extractors both the flattened,
+// stream-order text (see tokenText) and text grouped into rows by Y then X
+// (see groupRows), and keep whichever resolved more fields (extractBest) —
+// the parser package's label/value proximity heuristics still do the
+// actual matching either way, just over text ordered differently.
+type TextToken struct {
+	Text     string
+	X        float64
+	Y        float64
+	FontSize float64
+	Page     int
+}
 
-func (h headerType) String() string {
-	switch h {
-	case headerFranked:
-		return "Franked"
-	case headerUnfranked:
-		return "Unfranked"
-	case headerFrankingCredit:
-		return "Franking Credit"
-	case headerWithholdingTax:
-		return "Withholding Tax"
-	case headerSharesAllotted:
-		return "Shares Allotted"
-	case headerCostOfSharesAllotted:
-		return "Cost Of Shares Allotted"
-	case headerTotalShares:
-		return "Total Shares"
-	case headerTotalPayment:
-		return "Total Payment"
-	case headerOther:
-		return "Other"
-	default:
-		return "Unknown"
+// matrix is a PDF-style affine transform, [a b c d e f], applied to a point
+// as x' = a*x + c*y + e, y' = b*x + d*y + f.
+type matrix struct {
+	a, b, c, d, e, f float64
+}
+
+func identityMatrix() matrix {
+	return matrix{a: 1, d: 1}
+}
+
+// multiply returns the matrix equivalent to applying m and then n, i.e.
+// the combined transform for p*m*n.
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		a: m.a*n.a + m.b*n.c,
+		b: m.a*n.b + m.b*n.d,
+		c: m.c*n.a + m.d*n.c,
+		d: m.c*n.b + m.d*n.d,
+		e: m.e*n.a + m.f*n.c + n.e,
+		f: m.e*n.b + m.f*n.d + n.f,
 	}
 }
 
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m.a*x + m.c*y + m.e, m.b*x + m.d*y + m.f
+}
+
+func parseFloat(b []byte) float64 {
+	num, _ := strconv.ParseFloat(string(b), 64)
+	return num
+}
+
+func matrixFromArgs(args [][]byte) matrix {
+	return matrix{
+		a: parseFloat(args[0]),
+		b: parseFloat(args[1]),
+		c: parseFloat(args[2]),
+		d: parseFloat(args[3]),
+		e: parseFloat(args[4]),
+		f: parseFloat(args[5]),
+	}
+}
+
+// wordSpaceThreshold is the magnitude a TJ adjustment number (in thousandths
+// of a text space unit) must exceed for us to treat it as a genuine
+// inter-word gap rather than glyph kerning, and so synthesize a space for. Per the PDF
+// spec, a positive adjustment moves the next glyph left (tightens, ordinary
+// kerning) while a negative one moves it right (widens, the gap between
+// words), so only sufficiently negative numbers count.
+const wordSpaceThreshold = 120
+
 type textTracker struct {
-	text  []string
-	pdf   *pdfread.PdfReaderT
-	page  int
-	cmaps map[string]*cmapi.CharMapperT
-	fonts pdfread.DictionaryT
-	font  string
-	stack [][]byte
+	tokens   []TextToken
+	pdf      *pdfread.PdfReaderT
+	page     int
+	cmaps    map[string]*cmapi.CharMapperT
+	fonts    pdfread.DictionaryT
+	font     string
+	stack    [][]byte
+	ctm      matrix
+	tm       matrix
+	tlm      matrix
+	leading  float64
+	fontSize float64
+	gsStack  []matrix
+}
+
+func newTextTracker(pd *pdfread.PdfReaderT, page int) *textTracker {
+	return &textTracker{
+		pdf:   pd,
+		page:  page,
+		cmaps: make(map[string]*cmapi.CharMapperT),
+		ctm:   identityMatrix(),
+		tm:    identityMatrix(),
+		tlm:   identityMatrix(),
+	}
 }
 
 func (t *textTracker) cmap(font string) (r *cmapi.CharMapperT) {
@@ -86,16 +140,47 @@ func (t *textTracker) cmap(font string) (r *cmapi.CharMapperT) {
 	return
 }
 
+// emit appends str as a token at the current text position, except a lone
+// space glyph is folded into the previous token instead of starting a new
+// one, matching how PDF producers often draw run-on spaces as their own
+// Tj call.
+func (t *textTracker) emit(str string) {
+	if str == " " {
+		if len(t.tokens) > 0 {
+			t.tokens[len(t.tokens)-1].Text += " "
+		}
+		return
+	}
+
+	x, y := t.tm.multiply(t.ctm).apply(0, 0)
+	t.tokens = append(t.tokens, TextToken{
+		Text:     str,
+		X:        x,
+		Y:        y,
+		FontSize: t.fontSize,
+		Page:     t.page,
+	})
+}
+
+// translateText moves the text line matrix (and so the text matrix) by
+// (tx, ty) in unscaled text space, as done by the Td/TD/T* operators.
+func (t *textTracker) translateText(tx, ty float64) {
+	t.tlm = matrix{a: 1, d: 1, e: tx, f: ty}.multiply(t.tlm)
+	t.tm = t.tlm
+}
+
 func (t *textTracker) write(a []byte) {
 	tx := t.pdf.ForcedArray(a)
 	for k := range tx {
 		if tx[k][0] == '(' || tx[k][0] == '<' {
 			str := string(cmapi.Decode(ps.String(tx[k]), t.cmap(t.font)))
-			if str == " " && len(t.text) > 0 {
-				t.text[len(t.text)-1] += " "
-			} else {
-				t.text = append(t.text, str)
+			t.emit(str)
+		} else {
+			offset := parseFloat(tx[k])
+			if offset < -wordSpaceThreshold {
+				t.emit(" ")
 			}
+			t.translateText(-offset/1000*t.fontSize, 0)
 		}
 	}
 }
@@ -119,21 +204,50 @@ func (t *textTracker) process(data []byte) {
 		}
 
 		switch string(token) {
-		case "B", "B*", "F", "S", "b", "b*", "f", "f*", "h", "n", "s", "BT",
-			"ET", "T*", "EMC":
-		case "G", "J", "M", "g", "gs", "i", "j", "w", "TL", "Tc",
+		case "B", "B*", "F", "S", "b", "b*", "f", "f*", "h", "n", "s",
+			"ET", "EMC":
+		case "BT":
+			t.tm = identityMatrix()
+			t.tlm = identityMatrix()
+		case "q":
+			t.gsStack = append(t.gsStack, t.ctm)
+		case "Q":
+			if n := len(t.gsStack); n > 0 {
+				t.ctm = t.gsStack[n-1]
+				t.gsStack = t.gsStack[:n-1]
+			}
+		case "G", "J", "M", "g", "gs", "i", "j", "w", "Tc",
 			"Tr", "Ts", "Tw", "Tz", "BMC", "MP":
 			t.drop(1)
-		case "l", "m", "TD", "Td", "BDC", "DP":
+		case "TL":
+			t.leading = parseFloat(t.drop(1)[0])
+		case "l", "m", "BDC", "DP":
 			t.drop(2)
+		case "Td":
+			args := t.drop(2)
+			t.translateText(parseFloat(args[0]), parseFloat(args[1]))
+		case "TD":
+			args := t.drop(2)
+			tx, ty := parseFloat(args[0]), parseFloat(args[1])
+			t.leading = -ty
+			t.translateText(tx, ty)
+		case "T*":
+			t.translateText(0, -t.leading)
 		case "RG", "rg":
 			t.drop(3)
 		case "re", "v", "y", "K", "k":
 			t.drop(4)
-		case "c", "cm", "Tm":
+		case "c":
 			t.drop(6)
+		case "cm":
+			t.ctm = matrixFromArgs(t.drop(6)).multiply(t.ctm)
+		case "Tm":
+			t.tm = matrixFromArgs(t.drop(6))
+			t.tlm = t.tm
 		case "Tf":
-			t.font = string(t.drop(2)[0])
+			args := t.drop(2)
+			t.font = string(args[0])
+			t.fontSize = parseFloat(args[1])
 		case "'", "TJ", "Tj":
 			t.write(t.drop(1)[0])
 		case "\"":
@@ -144,160 +258,234 @@ func (t *textTracker) process(data []byte) {
 	}
 }
 
-func Process(filename string, holders []string) (s statement.Statement, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			rErr, ok := r.(error)
-			if ok {
-				err = errors.New("pdf: failed to process file: " +
-					rErr.Error() + ": " + string(debug.Stack()))
-			} else {
-				err = errors.New("pdf: failed to process file: " +
-					string(debug.Stack()))
-			}
+func recoverPdfErr(err *error) {
+	if r := recover(); r != nil {
+		rErr, ok := r.(error)
+		if ok {
+			*err = errors.New("pdf: failed to process file: " +
+				rErr.Error() + ": " + string(debug.Stack()))
+		} else {
+			*err = errors.New("pdf: failed to process file: " +
+				string(debug.Stack()))
+		}
+	}
+}
+
+func allPages(pd *pdfread.PdfReaderT) []int {
+	pages := make([]int, len(pd.Pages()))
+	for i := range pages {
+		pages[i] = i
+	}
+	return pages
+}
 
+func extractTokens(pd *pdfread.PdfReaderT, pages []int) []TextToken {
+	var tokens []TextToken
+	for _, page := range pages {
+		ttracker := newTextTracker(pd, page)
+		for _, content := range pd.ForcedArray(pd.Dic(pd.Pages()[page])["/Contents"]) {
+			_, data := pd.DecodedStream(content)
+			ttracker.process(data)
 		}
-	}()
+		tokens = append(tokens, ttracker.tokens...)
+	}
+	return tokens
+}
 
-	pd := pdfread.Load(filename)
-	_, data := pd.DecodedStream(pd.ForcedArray(pd.Dic(
-		pd.Pages()[0])["/Contents"])[0])
+func loadTokens(filename string, pages []int) (tokens []TextToken, err error) {
+	defer recoverPdfErr(&err)
 
-	ttracker := &textTracker{
-		pdf:   pd,
-		page:  0,
-		cmaps: make(map[string]*cmapi.CharMapperT),
+	pd := pdfread.Load(filename)
+	if pages == nil {
+		pages = allPages(pd)
 	}
 
-	ttracker.process(data)
-	return processText(ttracker.text, holders)
+	return extractTokens(pd, pages), nil
 }
 
-var numeralHeaders = map[headerType][]string{
-	headerFranked:              {"franked amount"},
-	headerUnfranked:            {"unfranked"},
-	headerWithholdingTax:       {"withholding tax", "less withholding tax"},
-	headerSharesAllotted:       {"number of shares allotted"},
-	headerCostOfSharesAllotted: {"cost of shares allotted"},
-	headerTotalShares:          {"total shares"},
-	headerTotalPayment:         {"total payment", "total amount"},
-	headerFrankingCredit:       {"franking credit"},
-	headerOther: {"dividend rate", "participating shares",
-		"participating holding", "net amount",
-		"dividend reinvestment plan amount",
-		"cash balance brought forward", "amount available from this payment",
-		"total amount available for reinvestment",
-		"cash balance carried forward"},
+// ExtractTokens parses every page of filename's content streams and
+// returns every piece of text drawn on them, in stream order, along with
+// its position, font size, and page number.
+func ExtractTokens(filename string) ([]TextToken, error) {
+	return loadTokens(filename, nil)
 }
 
-func numeralHeader(str string, state statement.Statement) (headerType, bool) {
-	str = strings.ToLower(str)
-	for headType, header := range numeralHeaders {
-		for _, headerText := range header {
-			if len(str) >= len(headerText) && str[:len(headerText)] == headerText {
-				switch headType {
-				case headerFranked:
-					if state.FrankedAmount.HasValue {
-						continue
-					}
-				case headerUnfranked:
-					if state.UnfrankedAmount.HasValue {
-						continue
-					}
-				case headerWithholdingTax:
-					if state.WithholdingTax.HasValue {
-						continue
-					}
-				case headerSharesAllotted:
-					if state.SharesAllotted != 0 {
-						continue
-					}
-				case headerCostOfSharesAllotted:
-					if state.CostOfSharesAllotted.HasValue {
-						continue
-					}
-				case headerTotalShares:
-					if state.TotalShares != 0 {
-						continue
-					}
-				case headerTotalPayment:
-					if state.TotalPayment.HasValue {
-						continue
-					}
-				case headerFrankingCredit:
-					if state.FrankingCredit.HasValue {
-						continue
-					}
-				}
+// tokenText discards every token's position, handing extract only the flat,
+// stream-order text it primarily matches fields against.
+func tokenText(tokens []TextToken) []string {
+	text := make([]string, len(tokens))
+	for i, tok := range tokens {
+		text[i] = tok.Text
+	}
+	return text
+}
 
-				return headType, true
-			}
+// rowYThreshold is how close two tokens' Y coordinates (in the same page
+// space as TextToken.Y) have to be for groupRows to consider them part of the same visual
+// row.
+const rowYThreshold = 2.0
+
+// groupRows buckets tokens into rows by Y proximity and, within each row,
+// orders them left to right by X, giving reading order for layouts where a
+// label and its value sit in columns that don't end up adjacent in stream
+// order. extractBest tries this alongside tokenText's stream order and
+// keeps whichever resolves more fields.
+func groupRows(tokens []TextToken) []string {
+	sorted := make([]TextToken, len(tokens))
+	copy(sorted, tokens)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Page != sorted[j].Page {
+			return sorted[i].Page < sorted[j].Page
 		}
+		return sorted[i].Y > sorted[j].Y
+	})
+
+	var rows []string
+	var row []TextToken
+	flush := func() {
+		if len(row) == 0 {
+			return
+		}
+		sort.SliceStable(row, func(i, j int) bool { return row[i].X < row[j].X })
+		var b strings.Builder
+		for _, tok := range row {
+			b.WriteString(tok.Text)
+		}
+		rows = append(rows, b.String())
+		row = nil
+	}
+
+	for _, tok := range sorted {
+		if len(row) > 0 &&
+			(tok.Page != row[0].Page || math.Abs(tok.Y-row[0].Y) > rowYThreshold) {
+			flush()
+		}
+		row = append(row, tok)
+	}
+	flush()
+
+	return rows
+}
+
+func extract(text []string, holders []string) (statement.Statement, error) {
+	for _, e := range Extractors {
+		if e.Detect(text) {
+			return e.Extract(text, holders)
+		}
+	}
+
+	return statement.Statement{}, errors.New("pdf: no extractor recognized this statement")
+}
+
+// extractBest tries extracting from tokens' flattened stream order, and
+// from rows grouped by Y/X position, and keeps whichever resolved more
+// fields, so a statement whose labels and values aren't adjacent in stream
+// order still has a chance of being matched correctly.
+func extractBest(tokens []TextToken, holders []string) (statement.Statement, error) {
+	streamOrder, streamErr := extract(tokenText(tokens), holders)
+	rowOrder, rowErr := extract(groupRows(tokens), holders)
+
+	switch {
+	case streamErr != nil && rowErr != nil:
+		return statement.Statement{}, streamErr
+	case streamErr != nil:
+		return rowOrder, nil
+	case rowErr != nil:
+		return streamOrder, nil
+	case resolvedFieldCount(rowOrder) > resolvedFieldCount(streamOrder):
+		return rowOrder, nil
+	default:
+		return streamOrder, nil
+	}
+}
+
+// Process extracts a statement from every page of filename, feeding all of
+// their text into a single parse so that fields split across pages (e.g. a
+// DRP allotment table on a second page) still get matched up. The first
+// registered Extractor that recognizes the text is used.
+func Process(filename string, holders []string) (statement.Statement, error) {
+	tokens, err := loadTokens(filename, nil)
+	if err != nil {
+		return statement.Statement{}, err
 	}
 
-	return 0, false
+	return extractBest(tokens, holders)
 }
 
-func processText(str []string, holders []string) (statement.Statement, error) {
+// ProcessPages behaves like Process, but only scans the given zero-indexed
+// pages, for callers that want to restrict the scan rather than read the
+// whole document.
+func ProcessPages(filename string, pages []int, holders []string) (statement.Statement, error) {
+	tokens, err := loadTokens(filename, pages)
+	if err != nil {
+		return statement.Statement{}, err
+	}
+
+	return extractBest(tokens, holders)
+}
+
+func applyMatch(state *statement.Statement, header parser.HeaderType, value float64) {
+	switch header {
+	case parser.HeaderFranked:
+		state.FrankedAmount = statement.NewDollar(value)
+	case parser.HeaderUnfranked:
+		state.UnfrankedAmount = statement.NewDollar(value)
+	case parser.HeaderWithholdingTax:
+		state.WithholdingTax = statement.NewDollar(value)
+	case parser.HeaderSharesAllotted:
+		state.SharesAllotted = int(value)
+	case parser.HeaderCostOfSharesAllotted:
+		state.CostOfSharesAllotted = statement.NewDollar(value)
+	case parser.HeaderTotalShares:
+		state.TotalShares = int(value)
+	case parser.HeaderTotalPayment:
+		state.TotalPayment = statement.NewDollar(value)
+	case parser.HeaderFrankingCredit:
+		state.FrankingCredit = statement.NewDollar(value)
+	}
+}
+
+// processText matches rules (one of Computershare, LinkMarketServices or
+// Boardroom) against str, the flattened text of an ASX-listed dividend
+// statement.
+func processText(str []string, holders []string, rules []parser.Rule) (statement.Statement, error) {
 	d := dispenser.NewDispenser(str)
 
 	var state statement.Statement
-	var numeralHeadTracker []headerType
+	p := parser.NewParser(rules)
 
 	for d.NextSentence() {
 		sentence := d.DumpSentence()
 
-		headType, foundNumeralHeader := numeralHeader(sentence, state)
-		if foundNumeralHeader {
-			numeralHeadTracker = append(numeralHeadTracker, headType)
+		if match, ok := p.Feed(strings.ToLower(sentence)); ok {
+			applyGenericMatch(&state, match)
 			continue
-		} else {
-			d.StartOfSentence()
-			newSentence := strings.Join(d.DumpNSentences(5), " ")
-
-			if len(state.AccountHolders) == 0 {
-				for _, holder := range holders {
-					if strings.Contains(strings.ToLower(newSentence),
-						strings.ToLower(holder)) {
-						state.AccountHolders = append(state.AccountHolders, holder)
-					}
+		}
+
+		d.StartOfSentence()
+		newSentence := strings.Join(d.DumpNSentences(5), " ")
+
+		if len(state.AccountHolders) == 0 {
+			for _, holder := range holders {
+				if strings.Contains(strings.ToLower(newSentence),
+					strings.ToLower(holder)) {
+					state.AccountHolders = append(state.AccountHolders, holder)
 				}
 			}
+		}
 
-			headType, foundNumeralHeader = numeralHeader(newSentence, state)
-			if foundNumeralHeader {
-				numeralHeadTracker = append(numeralHeadTracker, headType)
-				continue
-			}
+		if match, ok := p.Feed(strings.ToLower(newSentence)); ok {
+			applyGenericMatch(&state, match)
+			continue
 		}
 
 		findOtherData(sentence, d, &state)
 
-		if len(numeralHeadTracker) > 0 {
-			numD := dispenser.NewDispenserFromSentence(sentence)
-			numD.NextSentence()
-			found := numD.JumpNextNumeral()
-			if found && numD.Position() < 5 {
-				switch numeralHeadTracker[0] {
-				case headerFranked:
-					state.FrankedAmount = statement.NewDollar(numD.Numeral())
-				case headerUnfranked:
-					state.UnfrankedAmount = statement.NewDollar(numD.Numeral())
-				case headerWithholdingTax:
-					state.WithholdingTax = statement.NewDollar(numD.Numeral())
-				case headerSharesAllotted:
-					state.SharesAllotted = int(numD.Numeral())
-				case headerCostOfSharesAllotted:
-					state.CostOfSharesAllotted = statement.NewDollar(numD.Numeral())
-				case headerTotalShares:
-					state.TotalShares = int(numD.Numeral())
-				case headerTotalPayment:
-					state.TotalPayment = statement.NewDollar(numD.Numeral())
-				case headerFrankingCredit:
-					state.FrankingCredit = statement.NewDollar(numD.Numeral())
-				}
-
-				numeralHeadTracker = numeralHeadTracker[1:]
+		numD := dispenser.NewDispenserFromSentence(sentence)
+		numD.NextSentence()
+		if found := numD.JumpNextNumeral(); found && numD.Position() < 5 {
+			if match, ok := p.Feed(numD.Word()); ok {
+				applyMatch(&state, match.Header, numD.Numeral())
 			}
 		}
 	}
@@ -327,8 +515,7 @@ func findOtherData(sentence string, d *dispenser.Dispenser,
 		sent := d.DumpSentence()
 		t, err := time.Parse(dateLayout, sent)
 		if err != nil {
-			fmt.Println("failed to parse time:", err)
-			fmt.Println("from:", sent)
+			fmt.Fprintln(os.Stderr, "failed to parse time:", err, "from:", sent)
 		} else {
 			state.PaymentDate = t
 		}
@@ -0,0 +1,197 @@
+// Package report aggregates parsed dividend statements into an annual
+// summary and exports it in the form the ATO myTax dividends worksheet
+// expects.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1lann/go-tax/statement"
+)
+
+// Entry is the aggregated totals for one account holder's holding in one
+// ASX-listed entity over a financial year.
+type Entry struct {
+	Holder         string
+	ASXCode        string
+	Entity         string
+	Currency       string
+	Unfranked      statement.Dollar
+	Franked        statement.Dollar
+	FrankingCredit statement.Dollar
+	WithholdingTax statement.Dollar
+	GrossedUp      statement.Dollar
+}
+
+// Report is a financial year's aggregated dividend income, one Entry per
+// account holder/ASX code pair.
+type Report struct {
+	FinancialYear string
+	Entries       []Entry
+
+	// Skipped describes statements Summarize excluded, such as statements
+	// in a currency other than AUD or with no payment date to bucket by.
+	Skipped []string
+}
+
+type key struct {
+	holder   string
+	currency string
+	asxCode  string
+}
+
+// parseFinancialYear parses an Australian financial year such as
+// "2023-24" into its [start, end) date range (1 July to 30 June).
+func parseFinancialYear(fy string) (start, end time.Time, err error) {
+	parts := strings.Split(fy, "-")
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{},
+			fmt.Errorf("invalid tax year %q, want e.g. 2023-24", fy)
+	}
+
+	startYear, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid tax year %q: %v", fy, err)
+	}
+
+	start = time.Date(startYear, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end = time.Date(startYear+1, time.July, 1, 0, 0, 0, 0, time.UTC)
+	return start, end, nil
+}
+
+func addDollar(a, b statement.Dollar) statement.Dollar {
+	if !a.HasValue && !b.HasValue {
+		return a
+	}
+
+	return statement.Dollar{Cents: a.Cents + b.Cents, HasValue: true}
+}
+
+// Summarize aggregates the AUD-denominated statements that fall within the
+// given Australian financial year (e.g. "2023-24") by AccountHolders[0] and
+// ASXCode, computing totals for unfranked, franked, franking credit,
+// withholding tax, and the grossed-up dividend (franked + unfranked +
+// franking credit).
+//
+// The myTax dividends worksheet Summarize's output is built for only has
+// labels for AUD-sourced franked/unfranked dividends, so statements in
+// another currency, and statements with no payment date to bucket by, are
+// excluded from Entries and instead reported in Report.Skipped rather than
+// silently dropped.
+func Summarize(stmts []statement.Statement, fy string) (Report, error) {
+	start, end, err := parseFinancialYear(fy)
+	if err != nil {
+		return Report{}, err
+	}
+
+	r := Report{FinancialYear: fy}
+	index := make(map[key]int)
+
+	for _, s := range stmts {
+		var holder string
+		if len(s.AccountHolders) > 0 {
+			holder = s.AccountHolders[0]
+		}
+
+		if s.PaymentDate.IsZero() {
+			r.Skipped = append(r.Skipped, fmt.Sprintf(
+				"%s: no payment date extracted, excluded from %s", holder, fy))
+			continue
+		}
+
+		if s.Currency != "AUD" {
+			r.Skipped = append(r.Skipped, fmt.Sprintf(
+				"%s: %s statement, myTax dividends worksheet only covers AUD",
+				holder, s.Currency))
+			continue
+		}
+
+		if s.PaymentDate.Before(start) || !s.PaymentDate.Before(end) {
+			continue
+		}
+
+		k := key{holder: holder, currency: s.Currency, asxCode: s.ASXCode}
+		i, ok := index[k]
+		if !ok {
+			i = len(r.Entries)
+			index[k] = i
+			r.Entries = append(r.Entries, Entry{
+				Holder:   holder,
+				ASXCode:  s.ASXCode,
+				Entity:   s.Entity,
+				Currency: s.Currency,
+			})
+		}
+
+		e := &r.Entries[i]
+		e.Unfranked = addDollar(e.Unfranked, s.UnfrankedAmount)
+		e.Franked = addDollar(e.Franked, s.FrankedAmount)
+		e.FrankingCredit = addDollar(e.FrankingCredit, s.FrankingCredit)
+		e.WithholdingTax = addDollar(e.WithholdingTax, s.WithholdingTax)
+		e.GrossedUp = addDollar(e.GrossedUp, statement.Dollar{
+			Cents: s.UnfrankedAmount.Cents + s.FrankedAmount.Cents +
+				s.FrankingCredit.Cents,
+			HasValue: true,
+		})
+	}
+
+	return r, nil
+}
+
+func dollarString(d statement.Dollar) string {
+	if !d.HasValue {
+		return "0.00"
+	}
+
+	return strconv.FormatFloat(float64(d.Cents)/100, 'f', 2, 64)
+}
+
+// ExportMyTax writes r to w using the labels the ATO myTax dividends
+// worksheet expects (11S unfranked, 11T franked, 11U franking credit, 11V
+// TFN amounts withheld), one section per Entry followed by the financial
+// year's totals.
+func ExportMyTax(r Report, w io.Writer) error {
+	var totals Entry
+
+	for _, e := range r.Entries {
+		if _, err := fmt.Fprintf(w, "%s (%s):\n", e.Holder, e.ASXCode); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "  11S Unfranked amount: %s\n", dollarString(e.Unfranked)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "  11T Franked amount: %s\n", dollarString(e.Franked)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "  11U Franking credit: %s\n", dollarString(e.FrankingCredit)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "  11V TFN amounts withheld: %s\n\n", dollarString(e.WithholdingTax)); err != nil {
+			return err
+		}
+
+		totals.Unfranked = addDollar(totals.Unfranked, e.Unfranked)
+		totals.Franked = addDollar(totals.Franked, e.Franked)
+		totals.FrankingCredit = addDollar(totals.FrankingCredit, e.FrankingCredit)
+		totals.WithholdingTax = addDollar(totals.WithholdingTax, e.WithholdingTax)
+	}
+
+	if _, err := fmt.Fprintf(w, "Totals for %s:\n", r.FinancialYear); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "  11S Unfranked amount: %s\n", dollarString(totals.Unfranked)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "  11T Franked amount: %s\n", dollarString(totals.Franked)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "  11U Franking credit: %s\n", dollarString(totals.FrankingCredit)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "  11V TFN amounts withheld: %s\n", dollarString(totals.WithholdingTax))
+	return err
+}
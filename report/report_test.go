@@ -0,0 +1,119 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1lann/go-tax/statement"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSummarize(t *testing.T) {
+	stmts := []statement.Statement{
+		{
+			AccountHolders:  []string{"Jane Doe"},
+			ASXCode:         "BHP",
+			Currency:        "AUD",
+			PaymentDate:     date(2023, time.September, 15),
+			FrankedAmount:   statement.NewDollar(70),
+			FrankingCredit:  statement.NewDollar(30),
+			UnfrankedAmount: statement.Dollar{},
+		},
+		{
+			// Second BHP payment for the same holder in the same year,
+			// should be summed into the same Entry.
+			AccountHolders: []string{"Jane Doe"},
+			ASXCode:        "BHP",
+			Currency:       "AUD",
+			PaymentDate:    date(2024, time.March, 1),
+			FrankedAmount:  statement.NewDollar(50),
+		},
+		{
+			// Outside the 2023-24 financial year (1 Jul 2023 - 30 Jun 2024).
+			AccountHolders: []string{"Jane Doe"},
+			ASXCode:        "BHP",
+			Currency:       "AUD",
+			PaymentDate:    date(2022, time.December, 1),
+			FrankedAmount:  statement.NewDollar(999),
+		},
+		{
+			// No payment date extracted: skipped, not silently dropped.
+			AccountHolders: []string{"Jane Doe"},
+			ASXCode:        "CBA",
+			Currency:       "AUD",
+			FrankedAmount:  statement.NewDollar(10),
+		},
+		{
+			// Non-AUD: skipped, not merged into an AUD holder's totals.
+			AccountHolders: []string{"Jane Doe"},
+			Currency:       "USD",
+			PaymentDate:    date(2023, time.October, 1),
+			TotalPayment:   statement.NewDollar(200),
+		},
+	}
+
+	r, err := Summarize(stmts, "2023-24")
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+
+	if len(r.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1: %+v", len(r.Entries), r.Entries)
+	}
+
+	e := r.Entries[0]
+	if e.Holder != "Jane Doe" || e.ASXCode != "BHP" {
+		t.Errorf("entry is %s/%s, want Jane Doe/BHP", e.Holder, e.ASXCode)
+	}
+	if e.Franked.Cents != 12000 {
+		t.Errorf("Franked = %d cents, want 12000 (70+50)", e.Franked.Cents)
+	}
+	if e.FrankingCredit.Cents != 3000 {
+		t.Errorf("FrankingCredit = %d cents, want 3000", e.FrankingCredit.Cents)
+	}
+
+	if len(r.Skipped) != 2 {
+		t.Fatalf("got %d skipped, want 2: %+v", len(r.Skipped), r.Skipped)
+	}
+}
+
+func TestSummarizeInvalidYear(t *testing.T) {
+	if _, err := Summarize(nil, "not-a-year"); err == nil {
+		t.Fatal("Summarize with an invalid tax year: got nil error")
+	}
+}
+
+func TestExportMyTax(t *testing.T) {
+	r := Report{
+		FinancialYear: "2023-24",
+		Entries: []Entry{
+			{
+				Holder:         "Jane Doe",
+				ASXCode:        "BHP",
+				Franked:        statement.NewDollar(120),
+				FrankingCredit: statement.NewDollar(30),
+			},
+		},
+	}
+
+	var sb strings.Builder
+	if err := ExportMyTax(r, &sb); err != nil {
+		t.Fatalf("ExportMyTax: %v", err)
+	}
+
+	out := sb.String()
+	for _, want := range []string{
+		"Jane Doe (BHP):",
+		"11T Franked amount: 120.00",
+		"11U Franking credit: 30.00",
+		"Totals for 2023-24:",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q, got:\n%s", want, out)
+		}
+	}
+}
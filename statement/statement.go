@@ -27,6 +27,7 @@ type Statement struct {
 	ASXCode              string
 	AccountHolders       []string
 	PaymentDate          time.Time
+	Currency             string
 	TotalPayment         Dollar
 	FrankingCredit       Dollar
 	UnfrankedAmount      Dollar
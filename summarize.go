@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/1lann/go-tax/pdf"
+	"github.com/1lann/go-tax/report"
+	"github.com/1lann/go-tax/statement"
+)
+
+func runSummarize(args []string) error {
+	fs := flag.NewFlagSet("summarize", flag.ExitOnError)
+	holdersFile := fs.String("holders-file", "holders.txt",
+		"file listing account holder names, one per line")
+	output := fs.String("output", "", "file to write output to (default stdout)")
+	taxYear := fs.String("tax-year", "", "financial year to summarize, e.g. 2023-24")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("summarize requires at least one PDF file")
+	}
+	if *taxYear == "" {
+		return fmt.Errorf("summarize requires --tax-year, e.g. --tax-year 2023-24")
+	}
+
+	holders, err := loadHolders(*holdersFile)
+	if err != nil {
+		return err
+	}
+
+	var stmts []statement.Statement
+	var failures []string
+	for _, file := range fs.Args() {
+		s, err := pdf.Process(file, holders)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", file, err))
+			continue
+		}
+
+		stmts = append(stmts, s)
+	}
+
+	r, err := report.Summarize(stmts, *taxYear)
+	if err != nil {
+		return err
+	}
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer closeOutput()
+
+	if err := report.ExportMyTax(r, w); err != nil {
+		return err
+	}
+
+	reportSkipped(r.Skipped)
+	return reportFailures(failures)
+}